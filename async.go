@@ -0,0 +1,231 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy determines what an AsyncHandler does with an event when its
+// queue is full.
+type OverflowPolicy int8
+
+const (
+	// OverflowBlock blocks the logging goroutine until there's room in
+	// the queue.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropNewest drops the event being logged.
+	OverflowDropNewest
+
+	// OverflowDropOldest drops the oldest event in the queue to make room
+	// for the event being logged.
+	OverflowDropOldest
+
+	// OverflowDropBelowLevel drops the event being logged if its level is
+	// below the handler's drop level (see AsyncHandler.SetDropLevel).
+	// Events at or above the drop level block like OverflowBlock.
+	OverflowDropBelowLevel
+)
+
+// AsyncHandler wraps another Handler and emits events to it from a separate
+// goroutine so that slow handlers don't hold up the goroutines doing the
+// logging.  Events are copied into a bounded queue; what happens when the
+// queue is full is determined by the handler's OverflowPolicy.
+type AsyncHandler struct {
+	HandlerCommon
+
+	handler   Handler
+	policy    OverflowPolicy
+	dropLevel Level
+	dropped   uint64
+
+	mu       sync.Mutex
+	notEmpty sync.Cond
+	notFull  sync.Cond
+	queue    eventRing
+	closed   bool
+
+	// queued counts the events added to the queue and done counts the
+	// events taken out of it (either emitted or dropped) so that Flush
+	// knows when everything queued before it was called is done.
+	queued  uint64
+	done    uint64
+	waiters []asyncWaiter
+	exited  chan struct{}
+}
+
+type asyncWaiter struct {
+	target uint64
+	ch     chan struct{}
+}
+
+// NewAsyncHandler wraps h in an AsyncHandler whose queue holds up to size
+// events and starts the goroutine that emits them.  The AsyncHandler passes
+// every event through to h, which should filter events on its own level.
+func NewAsyncHandler(h Handler, size int, policy OverflowPolicy) *AsyncHandler {
+	ah := &AsyncHandler{
+		handler:   h,
+		policy:    policy,
+		dropLevel: WarnLevel,
+		queue:     makeEventRing(size),
+		exited:    make(chan struct{}),
+	}
+	ah.level = EverythingLevel
+	ah.notEmpty.L = &ah.mu
+	ah.notFull.L = &ah.mu
+	go ah.run()
+	return ah
+}
+
+// Handler gets the wrapped handler.
+func (h *AsyncHandler) Handler() Handler { return h.handler }
+
+// SetFormatter sets the wrapped handler's formatter.
+func (h *AsyncHandler) SetFormatter(formatter Formatter) {
+	h.HandlerCommon.SetFormatter(formatter)
+	h.handler.SetFormatter(formatter)
+}
+
+// SetDropLevel sets the level below which events are dropped when the queue
+// is full and the handler's policy is OverflowDropBelowLevel.  The default is
+// WarnLevel.
+func (h *AsyncHandler) SetDropLevel(level Level) {
+	h.mu.Lock()
+	h.dropLevel = level
+	h.mu.Unlock()
+}
+
+// Dropped gets the number of events that were dropped because the queue was
+// full or because they were emitted after the handler was closed.
+func (h *AsyncHandler) Dropped() uint64 { return atomic.LoadUint64(&h.dropped) }
+
+// Len gets the number of events waiting in the queue.
+func (h *AsyncHandler) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.queue.Len()
+}
+
+// Emit implements the Handler interface by queueing a copy of the event.
+func (h *AsyncHandler) Emit(event *Event) {
+	if event.Level < h.level {
+		return
+	}
+	// copy outside of the lock; the original goes back to its
+	// logger's pool as soon as Emit returns.
+	e := event.Clone()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for !h.closed && h.queue.full() {
+		switch h.policy {
+		case OverflowDropNewest:
+			atomic.AddUint64(&h.dropped, 1)
+			return
+		case OverflowDropOldest:
+			h.queue.pop()
+			h.done++
+			h.notifyWaiters()
+			atomic.AddUint64(&h.dropped, 1)
+			continue
+		case OverflowDropBelowLevel:
+			if e.Level < h.dropLevel {
+				atomic.AddUint64(&h.dropped, 1)
+				return
+			}
+		}
+		h.notFull.Wait()
+	}
+	if h.closed {
+		atomic.AddUint64(&h.dropped, 1)
+		return
+	}
+	h.queue.push(e)
+	h.queued++
+	h.notEmpty.Signal()
+}
+
+func (h *AsyncHandler) run() {
+	defer close(h.exited)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for {
+		for h.queue.Len() == 0 && !h.closed {
+			h.notEmpty.Wait()
+		}
+		e := h.queue.pop()
+		if e == nil {
+			// closed and drained.
+			return
+		}
+		h.notFull.Signal()
+		h.mu.Unlock()
+		h.handler.Emit(e)
+		h.mu.Lock()
+		h.done++
+		h.notifyWaiters()
+	}
+}
+
+// notifyWaiters must be called with h.mu held.
+func (h *AsyncHandler) notifyWaiters() {
+	waiters := h.waiters[:0]
+	for _, w := range h.waiters {
+		if w.target <= h.done {
+			close(w.ch)
+			continue
+		}
+		waiters = append(waiters, w)
+	}
+	for i := len(waiters); i < len(h.waiters); i++ {
+		h.waiters[i] = asyncWaiter{}
+	}
+	h.waiters = waiters
+}
+
+// Flush waits until every event queued before Flush was called is emitted
+// to the wrapped handler (or the context is done).  If the wrapped handler
+// has a Flush method, it is then flushed too.
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	h.mu.Lock()
+	if h.done < h.queued {
+		ch := make(chan struct{})
+		h.waiters = append(h.waiters, asyncWaiter{target: h.queued, ch: ch})
+		h.mu.Unlock()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	} else {
+		h.mu.Unlock()
+	}
+	if f, ok := h.handler.(interface {
+		Flush(ctx context.Context) error
+	}); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Close stops accepting events, waits for the queued events to be emitted,
+// and then closes the wrapped handler if it has a Close method.  Events
+// emitted after Close is called are dropped.
+func (h *AsyncHandler) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		<-h.exited
+		return nil
+	}
+	h.closed = true
+	h.notEmpty.Broadcast()
+	h.notFull.Broadcast()
+	h.mu.Unlock()
+	<-h.exited
+	if c, ok := h.handler.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsyncHandler(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var msgs []string
+	gate := make(chan struct{})
+	inner := HandlerFromEmitFunc(func(e *Event) {
+		<-gate
+		mu.Lock()
+		msgs = append(msgs, e.Msg)
+		mu.Unlock()
+	})
+	h := NewAsyncHandler(inner, 2, OverflowDropNewest)
+	L := GetLogger("", LoggerTemporary(), LoggerLevel(EverythingLevel), LoggerHandler(h))
+	L.Info0("first")
+	// wait for the worker to take "first" off of the queue.
+	for h.Len() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	L.Info0("second")
+	L.Info0("third")
+	L.Info0("dropped")
+	close(gate)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	got := append([]string(nil), msgs...)
+	mu.Unlock()
+	if len(got) != 3 || got[0] != "first" || got[1] != "second" || got[2] != "third" {
+		t.Errorf("emitted %q", got)
+	}
+	if n := h.Dropped(); n != 1 {
+		t.Errorf("dropped %d events; want 1", n)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	L.Info0("after close")
+	if n := h.Dropped(); n != 2 {
+		t.Errorf("dropped %d events; want 2", n)
+	}
+}
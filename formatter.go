@@ -78,3 +78,19 @@ func (f GoFormatter) Format(event *Event) string {
 		event.File, event.Line,
 	)
 }
+
+// MessageFormatter formats just the event's message with its arguments.  It
+// is meant for handlers whose destination records the rest of the event's
+// information separately.
+type MessageFormatter struct{}
+
+// Format implements the Formatter interface.
+func (MessageFormatter) Format(e *Event) string { return eventMessage(e) }
+
+// eventMessage formats the event's message with its arguments.
+func eventMessage(e *Event) string {
+	if len(e.Args) > 0 {
+		return fmt.Sprintf(e.Msg, e.Args...)
+	}
+	return e.Msg
+}
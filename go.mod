@@ -4,9 +4,8 @@ go 1.23.0
 
 toolchain go1.23.11
 
-require github.com/skillian/errors v0.0.0-20190910214200-f19f31b303bd
-
 require (
-	github.com/skillian/unsafereflect v0.0.0-20250707184903-b53a81dd1551 // indirect
-	golang.org/x/sys v0.34.0 // indirect
+	github.com/skillian/errors v0.0.0-20190910214200-f19f31b303bd
+	github.com/skillian/unsafereflect v0.0.0-20250707184903-b53a81dd1551
+	golang.org/x/sys v0.34.0
 )
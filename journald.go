@@ -0,0 +1,163 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JournaldSocketPath is the path to journald's native protocol socket.
+const JournaldSocketPath = "/run/systemd/journal/socket"
+
+// JournaldOption configures a JournaldHandler.
+type JournaldOption func(h *JournaldHandler) error
+
+// JournaldSocket sets the path of the socket that the handler sends events to.
+// The default is JournaldSocketPath.
+func JournaldSocket(path string) JournaldOption {
+	return func(h *JournaldHandler) error {
+		h.addr = &net.UnixAddr{Name: path, Net: "unixgram"}
+		return nil
+	}
+}
+
+// JournaldIdentifier sets the SYSLOG_IDENTIFIER field sent with every event.
+func JournaldIdentifier(identifier string) JournaldOption {
+	return JournaldField("SYSLOG_IDENTIFIER", identifier)
+}
+
+// JournaldField adds a field that is sent with every event.  Field names must
+// consist of uppercase letters, digits and underscores and must not start
+// with an underscore.
+func JournaldField(name, value string) JournaldOption {
+	return func(h *JournaldHandler) error {
+		if !isJournaldFieldName(name) {
+			return errors.New("invalid journald field name: " + strconv.Quote(name))
+		}
+		h.fields = append(h.fields, [2]string{name, value})
+		return nil
+	}
+}
+
+func isJournaldFieldName(name string) bool {
+	if name == "" || len(name) > 64 || name[0] == '_' || ('0' <= name[0] && name[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !('A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// JournaldHandler implements the Handler interface by writing events to the
+// systemd journal over its native protocol.  Events are sent with the
+// following fields:
+//
+//	MESSAGE		the formatted event
+//	PRIORITY	the event's Level as a syslog severity
+//	CODE_FILE	the event's File
+//	CODE_LINE	the event's Line
+//	CODE_FUNC	the event's FuncName
+//	LOGGER		the event's (i.e. its logger's) Name
+//
+// Events too large to send in a single datagram are passed to journald in a
+// sealed memfd on Linux.
+type JournaldHandler struct {
+	HandlerCommon
+
+	addr   *net.UnixAddr
+	fields [][2]string
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+	buf  bytes.Buffer
+}
+
+// NewJournaldHandler creates a handler that writes to the systemd journal.
+func NewJournaldHandler(options ...JournaldOption) (*JournaldHandler, error) {
+	h := &JournaldHandler{
+		addr: &net.UnixAddr{Name: JournaldSocketPath, Net: "unixgram"},
+	}
+	h.SetFormatter(MessageFormatter{})
+	for _, opt := range options {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	h.conn = conn
+	return h, nil
+}
+
+// Emit implements the Handler interface.
+func (h *JournaldHandler) Emit(event *Event) {
+	if event.Level < h.level {
+		return
+	}
+	msg := h.formatter.Format(event)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.buf.Reset()
+	appendJournaldField(&h.buf, "MESSAGE", strings.TrimRight(msg, "\n"))
+	appendJournaldField(&h.buf, "PRIORITY", strconv.Itoa(int(SyslogSeverity(event.Level))))
+	if event.File != "" {
+		appendJournaldField(&h.buf, "CODE_FILE", event.File)
+		appendJournaldField(&h.buf, "CODE_LINE", strconv.Itoa(event.Line))
+	}
+	if event.FuncName != "" {
+		appendJournaldField(&h.buf, "CODE_FUNC", event.FuncName)
+	}
+	if event.Name != "" {
+		appendJournaldField(&h.buf, "LOGGER", event.Name)
+	}
+	for _, f := range h.fields {
+		appendJournaldField(&h.buf, f[0], f[1])
+	}
+	if err := h.send(h.buf.Bytes()); err != nil {
+		panic(err)
+	}
+}
+
+// send must be called with h.mu held.
+func (h *JournaldHandler) send(data []byte) error {
+	_, _, err := h.conn.WriteMsgUnix(data, nil, h.addr)
+	if err == nil || !isMsgTooLarge(err) {
+		return err
+	}
+	return sendJournaldMemfd(h.conn, h.addr, data)
+}
+
+// appendJournaldField appends a field in journald's native protocol format.
+// Values with newlines in them are written with an explicit length.
+func appendJournaldField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if strings.IndexByte(value, '\n') == -1 {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	buf.Write(size[:])
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// Close closes the handler's socket.
+func (h *JournaldHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.conn.Close()
+}
@@ -0,0 +1,36 @@
+//go:build linux
+
+package logging
+
+import (
+	"errors"
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func isMsgTooLarge(err error) bool {
+	return errors.Is(err, unix.EMSGSIZE) || errors.Is(err, unix.ENOBUFS)
+}
+
+// sendJournaldMemfd writes data into a sealed memfd and passes its file
+// descriptor to journald, which is how journald accepts entries that are too
+// large for a single datagram.
+func sendJournaldMemfd(conn *net.UnixConn, addr *net.UnixAddr, data []byte) error {
+	fd, err := unix.MemfdCreate("logging-journald", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return err
+	}
+	f := os.NewFile(uintptr(fd), "logging-journald")
+	defer f.Close()
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	const seals = unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err = unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, seals); err != nil {
+		return err
+	}
+	_, _, err = conn.WriteMsgUnix(nil, unix.UnixRights(int(f.Fd())), addr)
+	return err
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package logging
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+func isMsgTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+func sendJournaldMemfd(conn *net.UnixConn, addr *net.UnixAddr, data []byte) error {
+	return errors.New("journald entry is too large to send without memfd support")
+}
@@ -126,3 +126,17 @@ type Event struct {
 	// Line holds the line number within the file where the error occurred.
 	Line int
 }
+
+// Clone creates a copy of the event that can be kept after the original is
+// passed to Logger.LogEvent, which returns the event and its Args slice to
+// the logger's pool to be reused.  The Args are copied shallowly.
+func (e *Event) Clone() *Event {
+	c := new(Event)
+	*c = *e
+	c.Args = nil
+	if len(e.Args) > 0 {
+		c.Args = make([]interface{}, len(e.Args))
+		copy(c.Args, e.Args)
+	}
+	return c
+}
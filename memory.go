@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// MemoryHandler keeps copies of the most recent events in memory and only
+// emits them to its target handler when an event at or above its flush level
+// arrives (and, optionally, when its buffer fills up).  This lets a program
+// log at a low level so that the events leading up to an error are available
+// without writing all of those events out when there is no error.
+//
+// The target handler still filters the events on its own level, so its level
+// should be at least as low as the MemoryHandler's.
+type MemoryHandler struct {
+	HandlerCommon
+
+	target      Handler
+	flushLevel  Level
+	flushOnFull bool
+
+	mu     sync.Mutex
+	buffer eventRing
+}
+
+// NewMemoryHandler creates a MemoryHandler that keeps up to capacity events
+// and emits them to target when an event at or above flushLevel is emitted.
+// If flushOnFull is true, the buffered events are also emitted when the
+// buffer fills up.  Otherwise, the oldest events are discarded to make room
+// for new ones.
+func NewMemoryHandler(target Handler, capacity int, flushLevel Level, flushOnFull bool) *MemoryHandler {
+	h := &MemoryHandler{
+		target:      target,
+		flushLevel:  flushLevel,
+		flushOnFull: flushOnFull,
+		buffer:      makeEventRing(capacity),
+	}
+	h.level = EverythingLevel
+	return h
+}
+
+// Target gets the handler that buffered events are flushed to.
+func (h *MemoryHandler) Target() Handler { return h.target }
+
+// SetFormatter sets the target handler's formatter.
+func (h *MemoryHandler) SetFormatter(formatter Formatter) {
+	h.HandlerCommon.SetFormatter(formatter)
+	h.target.SetFormatter(formatter)
+}
+
+// Emit implements the Handler interface.
+func (h *MemoryHandler) Emit(event *Event) {
+	if event.Level < h.level {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if event.Level >= h.flushLevel {
+		h.flush()
+		h.target.Emit(event)
+		return
+	}
+	h.buffer.push(event.Clone())
+	if h.flushOnFull && h.buffer.full() {
+		h.flush()
+	}
+}
+
+// flush must be called with h.mu held so that events are emitted to the
+// target in order.
+func (h *MemoryHandler) flush() {
+	for h.buffer.Len() > 0 {
+		h.target.Emit(h.buffer.pop())
+	}
+}
+
+// Flush emits the buffered events to the target handler.  If the target
+// handler has a Flush method, it is then flushed too.
+func (h *MemoryHandler) Flush(ctx context.Context) error {
+	h.mu.Lock()
+	h.flush()
+	h.mu.Unlock()
+	if f, ok := h.target.(interface {
+		Flush(ctx context.Context) error
+	}); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// Close emits the buffered events to the target handler and then closes the
+// target if it has a Close method.
+func (h *MemoryHandler) Close() error {
+	h.mu.Lock()
+	h.flush()
+	h.mu.Unlock()
+	if c, ok := h.target.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
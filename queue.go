@@ -0,0 +1,61 @@
+package logging
+
+// eventRing is a fixed-capacity FIFO queue of events.
+type eventRing struct {
+	events []*Event
+	head   int
+	length int
+}
+
+func makeEventRing(capacity int) eventRing {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return eventRing{events: make([]*Event, capacity)}
+}
+
+// Len gets the number of events in the ring.
+func (r *eventRing) Len() int { return r.length }
+
+// Cap gets the maximum number of events the ring can hold.
+func (r *eventRing) Cap() int { return len(r.events) }
+
+func (r *eventRing) full() bool { return r.length == len(r.events) }
+
+// at gets the i'th oldest event in the ring.
+func (r *eventRing) at(i int) *Event {
+	return r.events[(r.head+i)%len(r.events)]
+}
+
+// push adds an event to the ring.  If the ring is full, the oldest event is
+// removed to make room for it and returned.
+func (r *eventRing) push(e *Event) (old *Event) {
+	if r.full() {
+		old = r.pop()
+	}
+	r.events[(r.head+r.length)%len(r.events)] = e
+	r.length++
+	return
+}
+
+// pop removes the oldest event from the ring and returns it.  pop returns
+// nil if the ring is empty.
+func (r *eventRing) pop() *Event {
+	if r.length == 0 {
+		return nil
+	}
+	e := r.events[r.head]
+	r.events[r.head] = nil
+	r.head = (r.head + 1) % len(r.events)
+	r.length--
+	return e
+}
+
+// drain removes all of the events from the ring, oldest first.
+func (r *eventRing) drain() []*Event {
+	es := make([]*Event, r.length)
+	for i := range es {
+		es[i] = r.pop()
+	}
+	return es
+}
@@ -0,0 +1,234 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FileOption configures how the file-backed handlers create their files.
+type FileOption func(c *fileConfig) error
+
+// fileConfig holds the settings shared by the file-backed handlers.
+type fileConfig struct {
+	mode os.FileMode
+	uid  int
+	gid  int
+
+	// the rest of the fields are only used by the
+	// TimedRotatingFileHandler:
+
+	location     *time.Location
+	layout       string
+	compress     bool
+	maxAge       time.Duration
+	maxBackups   int
+	maxTotalSize int64
+}
+
+func defaultFileConfig() fileConfig {
+	return fileConfig{
+		mode:     0644,
+		uid:      -1,
+		gid:      -1,
+		location: time.Local,
+		layout:   "2006-01-02_15-04-05",
+	}
+}
+
+// FileMode sets the permission bits of the log files that a handler creates.
+// Existing files keep their permissions.
+func FileMode(mode os.FileMode) FileOption {
+	return func(c *fileConfig) error {
+		c.mode = mode.Perm()
+		return nil
+	}
+}
+
+// FileOwner sets the user and group IDs of the log files that a handler
+// creates.  An ID of -1 leaves that ID unchanged.
+func FileOwner(uid, gid int) FileOption {
+	return func(c *fileConfig) error {
+		c.uid = uid
+		c.gid = gid
+		return nil
+	}
+}
+
+// open opens the named file for appending (or truncates it if trunc is true)
+// and returns the file and its current size.  Files that did not already
+// exist get the configured mode and owner.
+func (c *fileConfig) open(name string, trunc bool) (*os.File, int64, error) {
+	_, err := os.Lstat(name)
+	created := os.IsNotExist(err)
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if trunc {
+		flag |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(name, flag, c.mode)
+	if err != nil {
+		return nil, 0, err
+	}
+	if created {
+		// OpenFile's mode is subject to the umask.
+		if err = f.Chmod(c.mode); err == nil && (c.uid != -1 || c.gid != -1) {
+			err = f.Chown(c.uid, c.gid)
+		}
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, fi.Size(), nil
+}
+
+// RotatingFileHandler implements the Handler interface by writing events into
+// a file that it owns.  When writing an event would make the file exceed its
+// maximum size, the file is rotated:  app.log is renamed to app.log.1, app.log.1
+// to app.log.2, and so on, up to the handler's backup count; the oldest
+// backup is removed.
+type RotatingFileHandler struct {
+	HandlerCommon
+
+	mu          sync.Mutex
+	config      fileConfig
+	filename    string
+	maxBytes    int64
+	backupCount int
+	file        *os.File
+	size        int64
+}
+
+// NewRotatingFileHandler opens (or creates) the named file and returns a
+// handler that rotates it once it would grow beyond maxBytes.  A maxBytes
+// of 0 or less disables rotation.  If backupCount is 0, the file is truncated
+// instead of being rotated.
+func NewRotatingFileHandler(filename string, maxBytes int64, backupCount int, options ...FileOption) (*RotatingFileHandler, error) {
+	h := &RotatingFileHandler{
+		config:      defaultFileConfig(),
+		filename:    filename,
+		maxBytes:    maxBytes,
+		backupCount: backupCount,
+	}
+	h.SetFormatter(DefaultFormatter{})
+	for _, opt := range options {
+		if err := opt(&h.config); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if h.file, h.size, err = h.config.open(filename, false); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Filename returns the name of the file that the handler writes to.
+func (h *RotatingFileHandler) Filename() string { return h.filename }
+
+// Emit implements the Handler interface.
+func (h *RotatingFileHandler) Emit(event *Event) {
+	if event.Level < h.level {
+		return
+	}
+	msg := h.formatter.Format(event)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.file == nil {
+		// the handler was closed; reopen the file so the event
+		// isn't lost.
+		f, size, err := h.config.open(h.filename, false)
+		if err != nil {
+			panic(err)
+		}
+		h.file, h.size = f, size
+	}
+	if h.shouldRotate(len(msg)) {
+		// If rotation fails, h.file still refers to a usable file
+		// and rotation is attempted again with the next event.
+		_ = h.rotate()
+	}
+	n, err := io.WriteString(h.file, msg)
+	h.size += int64(n)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (h *RotatingFileHandler) shouldRotate(n int) bool {
+	return h.maxBytes > 0 && h.size > 0 && h.size+int64(n) > h.maxBytes
+}
+
+// Rotate forces the handler to rotate its file.
+func (h *RotatingFileHandler) Rotate() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.rotate()
+}
+
+// rotate must be called with h.mu held.  If it returns an error, h.file is
+// left open on the (possibly unrotated) log file.
+func (h *RotatingFileHandler) rotate() (err error) {
+	if h.file != nil {
+		if err = h.file.Close(); err != nil {
+			return h.reopen(err)
+		}
+		h.file = nil
+	}
+	if h.backupCount > 0 {
+		for i := h.backupCount - 1; i > 0; i-- {
+			src := h.backupName(i)
+			if _, err := os.Lstat(src); err != nil {
+				continue
+			}
+			if err = os.Rename(src, h.backupName(i+1)); err != nil {
+				return h.reopen(err)
+			}
+		}
+		if err = os.Rename(h.filename, h.backupName(1)); err != nil {
+			return h.reopen(err)
+		}
+	}
+	f, size, err := h.config.open(h.filename, true)
+	if err != nil {
+		return h.reopen(err)
+	}
+	h.file, h.size = f, size
+	return nil
+}
+
+// reopen is called when rotation fails part way through to get back to a
+// state where events can still be written.  It returns the original error.
+func (h *RotatingFileHandler) reopen(err error) error {
+	if h.file != nil {
+		return err
+	}
+	if f, size, err2 := h.config.open(h.filename, false); err2 == nil {
+		h.file, h.size = f, size
+	}
+	return err
+}
+
+func (h *RotatingFileHandler) backupName(i int) string {
+	return h.filename + "." + strconv.Itoa(i)
+}
+
+// Close closes the handler's file.  Events emitted after the handler is
+// closed reopen the file.
+func (h *RotatingFileHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.file == nil {
+		return nil
+	}
+	err := h.file.Close()
+	h.file = nil
+	return err
+}
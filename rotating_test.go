@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFileHandler(t *testing.T) {
+	t.Parallel()
+
+	filename := filepath.Join(t.TempDir(), "app.log")
+	h, err := NewRotatingFileHandler(filename, 64, 2, FileMode(0600))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.SetLevel(DebugLevel)
+	h.SetFormatter(FormatterFunc(func(e *Event) string {
+		return e.Msg + "\n"
+	}))
+	for i := 0; i < 9; i++ {
+		h.Emit(&Event{Level: InfoLevel, Msg: strings.Repeat("x", 20)})
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", fi.Mode().Perm())
+	}
+	total := int64(0)
+	for _, name := range []string{filename, filename + ".1", filename + ".2"} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Size() > 64 {
+			t.Errorf("%s is %d bytes; want at most 64", name, fi.Size())
+		}
+		total += fi.Size()
+	}
+	if total != 9*21 {
+		t.Errorf("kept %d bytes; want %d", total, 9*21)
+	}
+	if _, err := os.Stat(filename + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups, got %v", err)
+	}
+}
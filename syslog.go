@@ -0,0 +1,422 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Facility is the syslog facility code that a SyslogHandler sends its
+// messages with.
+type Facility uint8
+
+// Syslog facilities as defined in RFC 5424.
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	FacilityNTP
+	FacilityAudit
+	FacilityAlert
+	FacilityClock
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// Severity is the syslog severity of a message.
+type Severity uint8
+
+// Syslog severities as defined in RFC 5424.
+const (
+	SeverityEmergency Severity = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInformational
+	SeverityDebug
+)
+
+// SyslogSeverity maps a logging Level to a syslog severity.
+func SyslogSeverity(level Level) Severity {
+	switch {
+	case level >= FatalLevel:
+		return SeverityCritical
+	case level >= ErrorLevel:
+		return SeverityError
+	case level >= WarnLevel:
+		return SeverityWarning
+	case level >= InfoLevel:
+		return SeverityInformational
+	default:
+		return SeverityDebug
+	}
+}
+
+// SyslogSDElement is an RFC 5424 structured data element.
+type SyslogSDElement struct {
+	// ID is the SD-ID of the element, e.g. "origin" or
+	// "custom@32473".
+	ID     string
+	Params []SyslogSDParam
+}
+
+// SyslogSDParam is a name-value pair within a SyslogSDElement.
+type SyslogSDParam struct {
+	Name  string
+	Value string
+}
+
+// SyslogOption configures a SyslogHandler.
+type SyslogOption func(h *SyslogHandler) error
+
+// SyslogFacility sets the facility that messages are sent with.  The default
+// is FacilityUser.
+func SyslogFacility(f Facility) SyslogOption {
+	return func(h *SyslogHandler) error {
+		if f > FacilityLocal7 {
+			return errors.New("invalid syslog facility " + strconv.Itoa(int(f)))
+		}
+		h.facility = f
+		return nil
+	}
+}
+
+// SyslogRFC3164 configures the handler to send messages in the older BSD
+// syslog format described in RFC 3164 instead of the RFC 5424 format.
+func SyslogRFC3164() SyslogOption {
+	return func(h *SyslogHandler) error {
+		h.rfc3164 = true
+		return nil
+	}
+}
+
+// SyslogHostname sets the hostname that messages are sent with.  The default
+// is the result of os.Hostname.
+func SyslogHostname(hostname string) SyslogOption {
+	return func(h *SyslogHandler) error {
+		h.hostname = hostname
+		return nil
+	}
+}
+
+// SyslogAppName sets the application name (or "tag" in RFC 3164) that
+// messages are sent with.  The default is the base name of the executable.
+func SyslogAppName(name string) SyslogOption {
+	return func(h *SyslogHandler) error {
+		h.appName = name
+		return nil
+	}
+}
+
+// SyslogProcID sets the process ID that messages are sent with.  The default
+// is the current process ID.
+func SyslogProcID(procID string) SyslogOption {
+	return func(h *SyslogHandler) error {
+		h.procID = procID
+		return nil
+	}
+}
+
+// SyslogMsgID sets the RFC 5424 MSGID that messages are sent with.  By
+// default, no MSGID is sent.
+func SyslogMsgID(msgID string) SyslogOption {
+	return func(h *SyslogHandler) error {
+		h.msgID = msgID
+		return nil
+	}
+}
+
+// SyslogStructuredData sets a function that gets the RFC 5424 structured data
+// to send with each event.
+func SyslogStructuredData(f func(e *Event) []SyslogSDElement) SyslogOption {
+	return func(h *SyslogHandler) error {
+		h.structuredData = f
+		return nil
+	}
+}
+
+// SyslogOctetCounting configures whether messages sent over stream
+// connections (e.g. TCP) are framed with octet counting as described in
+// RFC 6587.  If false, messages are terminated with a newline instead.  The
+// default is true.
+func SyslogOctetCounting(v bool) SyslogOption {
+	return func(h *SyslogHandler) error {
+		h.octetCounting = v
+		return nil
+	}
+}
+
+// SyslogDialTimeout sets the timeout for connecting (and reconnecting) to
+// the syslog server.
+func SyslogDialTimeout(d time.Duration) SyslogOption {
+	return func(h *SyslogHandler) error {
+		h.dialTimeout = d
+		return nil
+	}
+}
+
+// SyslogHandler implements the Handler interface by sending events to a
+// syslog server.  If sending an event fails, the handler reconnects and
+// tries again once.
+type SyslogHandler struct {
+	HandlerCommon
+
+	network        string
+	addr           string
+	facility       Facility
+	rfc3164        bool
+	hostname       string
+	appName        string
+	procID         string
+	msgID          string
+	structuredData func(e *Event) []SyslogSDElement
+	octetCounting  bool
+	dialTimeout    time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	stream bool
+	buf    bytes.Buffer
+}
+
+// NewSyslogHandler connects to the syslog server at the given network address
+// (e.g. "udp", "localhost:514" or "unix", "/dev/log").  If network is empty,
+// the handler connects to the local syslog server's unix socket.
+func NewSyslogHandler(network, addr string, options ...SyslogOption) (*SyslogHandler, error) {
+	h := &SyslogHandler{
+		network:       network,
+		addr:          addr,
+		facility:      FacilityUser,
+		appName:       filepath.Base(os.Args[0]),
+		procID:        strconv.Itoa(os.Getpid()),
+		octetCounting: true,
+		dialTimeout:   10 * time.Second,
+	}
+	h.hostname, _ = os.Hostname()
+	h.SetFormatter(MessageFormatter{})
+	for _, opt := range options {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+	if err := h.connect(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// connect must be called with h.mu held.
+func (h *SyslogHandler) connect() (err error) {
+	if h.network != "" {
+		return h.dial(h.network, h.addr)
+	}
+	addrs := []string{h.addr}
+	if h.addr == "" {
+		addrs = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+	}
+	for _, addr := range addrs {
+		for _, network := range []string{"unixgram", "unix"} {
+			if err = h.dial(network, addr); err == nil {
+				return nil
+			}
+		}
+	}
+	return
+}
+
+func (h *SyslogHandler) dial(network, addr string) (err error) {
+	if h.conn, err = net.DialTimeout(network, addr, h.dialTimeout); err != nil {
+		return err
+	}
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+		h.stream = false
+	default:
+		h.stream = true
+	}
+	return nil
+}
+
+// Emit implements the Handler interface.
+func (h *SyslogHandler) Emit(event *Event) {
+	if event.Level < h.level {
+		return
+	}
+	msg := strings.TrimRightFunc(h.formatter.Format(event), unicode.IsSpace)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	err := h.write(event, msg)
+	if err != nil {
+		if h.conn != nil {
+			h.conn.Close()
+			h.conn = nil
+		}
+		err = h.write(event, msg)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// write must be called with h.mu held.
+func (h *SyslogHandler) write(event *Event, msg string) error {
+	if h.conn == nil {
+		if err := h.connect(); err != nil {
+			return err
+		}
+	}
+	h.buf.Reset()
+	if h.rfc3164 {
+		h.appendRFC3164(&h.buf, event, msg)
+	} else {
+		h.appendRFC5424(&h.buf, event, msg)
+	}
+	frame := h.buf.Bytes()
+	if h.stream {
+		if h.octetCounting {
+			frame = append(strconv.AppendInt(nil, int64(len(frame)), 10), ' ')
+			frame = append(frame, h.buf.Bytes()...)
+		} else {
+			h.buf.WriteByte('\n')
+			frame = h.buf.Bytes()
+		}
+	}
+	_, err := h.conn.Write(frame)
+	return err
+}
+
+func (h *SyslogHandler) priority(event *Event) int {
+	return int(h.facility)*8 + int(SyslogSeverity(event.Level))
+}
+
+func (h *SyslogHandler) appendRFC5424(buf *bytes.Buffer, event *Event, msg string) {
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(h.priority(event)))
+	buf.WriteString(">1 ")
+	if event.Time.IsZero() {
+		buf.WriteByte('-')
+	} else {
+		buf.WriteString(event.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+	}
+	for _, field := range [...]struct {
+		value string
+		max   int
+	}{
+		{h.hostname, 255},
+		{h.appName, 48},
+		{h.procID, 128},
+		{h.msgID, 32},
+	} {
+		buf.WriteByte(' ')
+		appendSyslogHeaderField(buf, field.value, field.max)
+	}
+	buf.WriteByte(' ')
+	var elems []SyslogSDElement
+	if h.structuredData != nil {
+		elems = h.structuredData(event)
+	}
+	if len(elems) == 0 {
+		buf.WriteByte('-')
+	}
+	for _, elem := range elems {
+		buf.WriteByte('[')
+		appendSyslogHeaderField(buf, elem.ID, 32)
+		for _, param := range elem.Params {
+			buf.WriteByte(' ')
+			appendSyslogHeaderField(buf, param.Name, 32)
+			buf.WriteString(`="`)
+			for _, r := range param.Value {
+				switch r {
+				case '"', '\\', ']':
+					buf.WriteByte('\\')
+				}
+				buf.WriteRune(r)
+			}
+			buf.WriteByte('"')
+		}
+		buf.WriteByte(']')
+	}
+	if msg != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(msg)
+	}
+}
+
+func (h *SyslogHandler) appendRFC3164(buf *bytes.Buffer, event *Event, msg string) {
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(h.priority(event)))
+	buf.WriteByte('>')
+	t := event.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	buf.WriteString(t.Format(time.Stamp))
+	buf.WriteByte(' ')
+	appendSyslogHeaderField(buf, h.hostname, 255)
+	buf.WriteByte(' ')
+	appendSyslogHeaderField(buf, h.appName, 32)
+	if h.procID != "" {
+		buf.WriteByte('[')
+		buf.WriteString(h.procID)
+		buf.WriteByte(']')
+	}
+	buf.WriteString(": ")
+	buf.WriteString(msg)
+}
+
+// appendSyslogHeaderField appends s to buf, replacing characters that aren't
+// allowed in syslog header fields and truncating it to max bytes.  Empty
+// fields are written as "-".
+func appendSyslogHeaderField(buf *bytes.Buffer, s string, max int) {
+	if s == "" {
+		buf.WriteByte('-')
+		return
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c < '!' || c > '~', c == '=', c == ']', c == '"':
+			c = '_'
+		}
+		buf.WriteByte(c)
+	}
+}
+
+// Close closes the connection to the syslog server.
+func (h *SyslogHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogHandlerUDP(t *testing.T) {
+	t.Parallel()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	h, err := NewSyslogHandler(
+		"udp", pc.LocalAddr().String(),
+		SyslogFacility(FacilityLocal3),
+		SyslogHostname("host"),
+		SyslogAppName("app"),
+		SyslogProcID("42"),
+		SyslogMsgID("ID47"),
+		SyslogStructuredData(func(e *Event) []SyslogSDElement {
+			return []SyslogSDElement{{
+				ID:     "origin@32473",
+				Params: []SyslogSDParam{{Name: "logger", Value: `a"b]`}},
+			}}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	h.SetLevel(EverythingLevel)
+	h.Emit(&Event{
+		Time:  time.Date(2003, 10, 11, 22, 14, 15, 3000, time.UTC),
+		Level: FatalLevel,
+		Msg:   "disk %s is full",
+		Args:  []interface{}{"/var"},
+	})
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `<154>1 2003-10-11T22:14:15.000003Z host app 42 ID47 ` +
+		`[origin@32473 logger="a\"b\]"] disk /var is full`
+	if got := string(buf[:n]); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSyslogHandlerTCP(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			s, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return
+			}
+			frame := make([]byte, n)
+			if _, err := io.ReadFull(r, frame); err != nil {
+				return
+			}
+			lines <- string(frame)
+		}
+	}()
+	h, err := NewSyslogHandler("tcp", l.Addr().String(), SyslogRFC3164(), SyslogHostname("host"), SyslogAppName("app"), SyslogProcID("7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	h.Emit(&Event{
+		Time:  time.Date(2003, 10, 1, 22, 14, 15, 0, time.UTC),
+		Level: WarnLevel,
+		Msg:   "line 1\nline 2",
+	})
+	select {
+	case got := <-lines:
+		const want = "<12>Oct  1 22:14:15 host app[7]: line 1\nline 2"
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+}
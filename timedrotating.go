@@ -0,0 +1,421 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileLocation sets the time zone that a TimedRotatingFileHandler uses to
+// schedule rotations and to name its archives.  The default is time.Local.
+func FileLocation(loc *time.Location) FileOption {
+	return func(c *fileConfig) error {
+		if loc == nil {
+			return errors.New("nil file location")
+		}
+		c.location = loc
+		return nil
+	}
+}
+
+// FileTimeLayout sets the time.Time layout that a TimedRotatingFileHandler
+// appends to its file name to name its archives.  The default layout is
+// "2006-01-02_15-04-05".
+func FileTimeLayout(layout string) FileOption {
+	return func(c *fileConfig) error {
+		if layout == "" {
+			return errors.New("empty file time layout")
+		}
+		c.layout = layout
+		return nil
+	}
+}
+
+// FileCompress configures a TimedRotatingFileHandler to gzip its archives
+// after they're rotated.
+func FileCompress(compress bool) FileOption {
+	return func(c *fileConfig) error {
+		c.compress = compress
+		return nil
+	}
+}
+
+// FileMaxAge configures a TimedRotatingFileHandler to remove archives that
+// haven't been written to within the given duration.  0 keeps all archives.
+func FileMaxAge(d time.Duration) FileOption {
+	return func(c *fileConfig) error {
+		c.maxAge = d
+		return nil
+	}
+}
+
+// FileMaxBackups configures a TimedRotatingFileHandler to keep at most n
+// archives.  0 keeps all archives.
+func FileMaxBackups(n int) FileOption {
+	return func(c *fileConfig) error {
+		c.maxBackups = n
+		return nil
+	}
+}
+
+// FileMaxTotalSize configures a TimedRotatingFileHandler to remove its oldest
+// archives until the log file and its archives take up at most size bytes.
+// The current log file is never removed.  0 does not limit the size.
+func FileMaxTotalSize(size int64) FileOption {
+	return func(c *fileConfig) error {
+		c.maxTotalSize = size
+		return nil
+	}
+}
+
+// RotationSchedule decides when a TimedRotatingFileHandler rotates its file.
+type RotationSchedule interface {
+	// Next returns the first time after t that the file should be
+	// rotated.  t is already in the handler's location.
+	Next(t time.Time) time.Time
+}
+
+var (
+	// RotateMidnight rotates files at midnight.
+	RotateMidnight = RotateEvery(24 * time.Hour)
+
+	// RotateHourly rotates files at the top of every hour.
+	RotateHourly = RotateEvery(time.Hour)
+)
+
+// RotateEvery creates a RotationSchedule that rotates files every d.  If d
+// evenly divides a day, rotations are aligned to the wall clock starting at
+// midnight (e.g. every 15 minutes rotates at :00, :15, :30 and :45).
+// Otherwise, files are rotated d after they were started.
+func RotateEvery(d time.Duration) RotationSchedule { return rotateEvery(d) }
+
+type rotateEvery time.Duration
+
+func (r rotateEvery) Next(t time.Time) time.Time {
+	const day = 24 * time.Hour
+	d := time.Duration(r)
+	if d <= 0 || day%d != 0 {
+		return t.Add(d)
+	}
+	y, m, dd := t.Date()
+	hh, mm, ss := t.Clock()
+	elapsed := time.Duration(hh)*time.Hour +
+		time.Duration(mm)*time.Minute +
+		time.Duration(ss)*time.Second +
+		time.Duration(t.Nanosecond())
+	next := (elapsed/d + 1) * d
+	// time.Date normalizes wall-clock values, so this also works across
+	// daylight saving time transitions.
+	return time.Date(
+		y, m, dd, 0, 0,
+		int(next/time.Second), int(next%time.Second),
+		t.Location(),
+	)
+}
+
+// TimedRotatingFileHandler implements the Handler interface by writing
+// events into a file that it rotates on a schedule.  Rotated files are
+// renamed by appending the time that they were started to their name and
+// can optionally be compressed and removed based on their age, count, and
+// total size.
+type TimedRotatingFileHandler struct {
+	HandlerCommon
+
+	mu       sync.Mutex
+	config   fileConfig
+	filename string
+	schedule RotationSchedule
+	file     *os.File
+	start    time.Time
+	next     time.Time
+
+	// wg tracks the background compression and cleanup.
+	wg sync.WaitGroup
+
+	// cleanMu serializes the background cleanup.
+	cleanMu sync.Mutex
+}
+
+// NewTimedRotatingFileHandler opens (or creates) the named file and returns a
+// handler that rotates it according to the given schedule.
+func NewTimedRotatingFileHandler(filename string, schedule RotationSchedule, options ...FileOption) (*TimedRotatingFileHandler, error) {
+	h := &TimedRotatingFileHandler{
+		config:   defaultFileConfig(),
+		filename: filename,
+		schedule: schedule,
+	}
+	h.SetFormatter(DefaultFormatter{})
+	for _, opt := range options {
+		if err := opt(&h.config); err != nil {
+			return nil, err
+		}
+	}
+	if err := h.open(time.Now()); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Filename returns the name of the file that the handler writes to.
+func (h *TimedRotatingFileHandler) Filename() string { return h.filename }
+
+// open opens the log file.  If the file already has data in it, the
+// current segment is considered to have started when the file was last
+// modified so that restarting a program doesn't postpone its rotation.
+func (h *TimedRotatingFileHandler) open(now time.Time) error {
+	f, size, err := h.config.open(h.filename, false)
+	if err != nil {
+		return err
+	}
+	start := now
+	if size > 0 {
+		if fi, err := f.Stat(); err == nil {
+			start = fi.ModTime()
+		}
+	}
+	h.file = f
+	h.start = start.In(h.config.location)
+	h.next = h.schedule.Next(h.start)
+	return nil
+}
+
+// Emit implements the Handler interface.
+func (h *TimedRotatingFileHandler) Emit(event *Event) {
+	if event.Level < h.level {
+		return
+	}
+	msg := h.formatter.Format(event)
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.file == nil {
+		// the handler was closed; reopen the file so the event
+		// isn't lost.
+		if err := h.open(now); err != nil {
+			panic(err)
+		}
+	}
+	if !now.Before(h.next) {
+		// If rotation fails, h.file still refers to a usable file
+		// and rotation is attempted again with the next event.
+		_ = h.rotate(now)
+	}
+	if _, err := io.WriteString(h.file, msg); err != nil {
+		panic(err)
+	}
+}
+
+// Rotate forces the handler to rotate its file.
+func (h *TimedRotatingFileHandler) Rotate() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.rotate(time.Now())
+}
+
+// rotate must be called with h.mu held.  If it returns an error, h.file is
+// left open on the (possibly unrotated) log file.
+func (h *TimedRotatingFileHandler) rotate(now time.Time) error {
+	if h.file != nil {
+		if err := h.file.Close(); err != nil {
+			return h.reopen(now, err)
+		}
+		h.file = nil
+	}
+	archive := h.archiveName()
+	if err := os.Rename(h.filename, archive); err != nil {
+		return h.reopen(now, err)
+	}
+	f, _, err := h.config.open(h.filename, true)
+	if err != nil {
+		return h.reopen(now, err)
+	}
+	h.file = f
+	h.start = now.In(h.config.location)
+	h.next = h.schedule.Next(h.start)
+	h.wg.Add(1)
+	go h.finish(archive)
+	return nil
+}
+
+// reopen is called when rotation fails part way through to get back to a
+// state where events can still be written.  It returns the original error.
+func (h *TimedRotatingFileHandler) reopen(now time.Time, err error) error {
+	if h.file == nil {
+		_ = h.open(now)
+	}
+	return err
+}
+
+// archiveName gets an unused name for the current segment's archive.
+func (h *TimedRotatingFileHandler) archiveName() string {
+	name := h.filename + "." + h.start.Format(h.config.layout)
+	for i := 1; ; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = name + "." + strconv.Itoa(i)
+		}
+		if !exists(candidate) && !exists(candidate+".gz") {
+			return candidate
+		}
+	}
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+// finish compresses a newly rotated archive and then applies the retention
+// limits.  It runs in its own goroutine.
+func (h *TimedRotatingFileHandler) finish(archive string) {
+	defer h.wg.Done()
+	h.cleanMu.Lock()
+	defer h.cleanMu.Unlock()
+	if h.config.compress {
+		// if compression fails, the uncompressed archive is kept.
+		_ = h.compress(archive)
+	}
+	_ = h.clean(time.Now())
+}
+
+func (h *TimedRotatingFileHandler) compress(archive string) (err error) {
+	fi, err := os.Stat(archive)
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	gzname := archive + ".gz"
+	dst, _, err := h.config.open(gzname, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(gzname)
+		}
+	}()
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(archive)
+	zw.ModTime = fi.ModTime()
+	if _, err = io.Copy(zw, src); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	// keep the modification time so the retention limits treat
+	// the compressed archive the same as the original.
+	if err = os.Chtimes(gzname, fi.ModTime(), fi.ModTime()); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(archive)
+}
+
+// archives gets the handler's archives, newest first.
+func (h *TimedRotatingFileHandler) archives() ([]os.FileInfo, error) {
+	dir := filepath.Dir(h.filename)
+	prefix := filepath.Base(h.filename) + "."
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var fis []os.FileInfo
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if !h.isArchiveSuffix(name[len(prefix):]) {
+			continue
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		fis = append(fis, fi)
+	}
+	sort.Slice(fis, func(i, j int) bool {
+		return fis[i].ModTime().After(fis[j].ModTime())
+	})
+	return fis, nil
+}
+
+// isArchiveSuffix checks whether the part of a file name after the log file's
+// name is a timestamp, optionally followed by a collision counter and/or
+// a ".gz" extension.
+func (h *TimedRotatingFileHandler) isArchiveSuffix(s string) bool {
+	s = strings.TrimSuffix(s, ".gz")
+	if _, err := time.ParseInLocation(h.config.layout, s, h.config.location); err == nil {
+		return true
+	}
+	i := strings.LastIndexByte(s, '.')
+	if i == -1 {
+		return false
+	}
+	if _, err := strconv.Atoi(s[i+1:]); err != nil {
+		return false
+	}
+	_, err := time.ParseInLocation(h.config.layout, s[:i], h.config.location)
+	return err == nil
+}
+
+// clean removes the archives that exceed the configured limits.
+func (h *TimedRotatingFileHandler) clean(now time.Time) error {
+	c := &h.config
+	if c.maxAge <= 0 && c.maxBackups <= 0 && c.maxTotalSize <= 0 {
+		return nil
+	}
+	fis, err := h.archives()
+	if err != nil {
+		return err
+	}
+	total := int64(0)
+	if fi, err := os.Stat(h.filename); err == nil {
+		total = fi.Size()
+	}
+	dir := filepath.Dir(h.filename)
+	for i, fi := range fis {
+		total += fi.Size()
+		remove := (c.maxBackups > 0 && i >= c.maxBackups) ||
+			(c.maxAge > 0 && now.Sub(fi.ModTime()) > c.maxAge) ||
+			(c.maxTotalSize > 0 && total > c.maxTotalSize)
+		if !remove {
+			continue
+		}
+		if err2 := os.Remove(filepath.Join(dir, fi.Name())); err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+// Close closes the handler's file and waits for any background compression
+// and cleanup to finish.  Events emitted after the handler is closed reopen
+// the file.
+func (h *TimedRotatingFileHandler) Close() error {
+	h.mu.Lock()
+	var err error
+	if h.file != nil {
+		err = h.file.Close()
+		h.file = nil
+	}
+	h.mu.Unlock()
+	h.wg.Wait()
+	return err
+}